@@ -0,0 +1,106 @@
+package golog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Field is a single piece of structured context attached to a log record,
+// either via Logger.With or one of the *KV methods.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Record is the information passed to a Handler for a single log call.
+type Record struct {
+	Time   time.Time
+	Level  int
+	Logger string
+	File   string
+	Line   int
+	Flag   int // the Logger's flags at the time of the call
+	Msg    string
+	Fields []Field
+}
+
+// Handler renders a Record by appending it to buf, which write() draws from
+// bufPool and writes out once populated. TextHandler reproduces golog's
+// historical plain-text format; JSONHandler emits one JSON object per line
+// for log aggregators. Appending to the caller's buffer, rather than
+// returning an owned []byte, means the hot Debugf/Infof path does not
+// allocate a fresh output buffer per call, the same way Output does not.
+type Handler interface {
+	Handle(buf *[]byte, r *Record)
+}
+
+// TextHandler is the default Handler and matches the format Output has
+// always produced: "<prefix> <header>msg", with any fields appended as
+// space-separated key=value pairs.
+type TextHandler struct{}
+
+func (TextHandler) Handle(buf *[]byte, r *Record) {
+	prefix := levelString[r.Level] + " " + r.Logger
+	formatHeader(buf, r.Flag, r.Time, r.File, r.Line, prefix)
+	*buf = append(*buf, r.Msg...)
+	if len(r.Fields) > 0 {
+		if len(r.Msg) > 0 && r.Msg[len(r.Msg)-1] == '\n' {
+			*buf = (*buf)[:len(*buf)-1]
+		}
+		for _, f := range r.Fields {
+			*buf = append(*buf, ' ')
+			*buf = append(*buf, f.Key...)
+			*buf = append(*buf, '=')
+			*buf = append(*buf, formatValue(f.Value)...)
+		}
+	}
+	if len(*buf) == 0 || (*buf)[len(*buf)-1] != '\n' {
+		*buf = append(*buf, '\n')
+	}
+}
+
+// JSONHandler emits one JSON object per record, honoring the Logger's
+// Ldate/Ltime/Lshortfile flags to decide which of time/caller to include.
+type JSONHandler struct{}
+
+func (JSONHandler) Handle(buf *[]byte, r *Record) {
+	*buf = append(*buf, '{')
+	if r.Flag&(Ldate|Ltime|Lmicroseconds) != 0 {
+		appendJSONString(buf, "time", r.Time.Format(time.RFC3339Nano))
+		*buf = append(*buf, ',')
+	}
+	appendJSONString(buf, "level", levelName(r.Level))
+	*buf = append(*buf, ',')
+	appendJSONString(buf, "logger", r.Logger)
+	*buf = append(*buf, ',')
+	if r.Flag&(Lshortfile|Llongfile) != 0 {
+		appendJSONString(buf, "caller", r.File+":"+strconv.Itoa(r.Line))
+		*buf = append(*buf, ',')
+	}
+	appendJSONString(buf, "msg", r.Msg)
+	for _, f := range r.Fields {
+		*buf = append(*buf, ',')
+		appendJSONString(buf, f.Key, formatValue(f.Value))
+	}
+	*buf = append(*buf, '}', '\n')
+}
+
+func formatValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	if s, ok := v.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprint(v)
+}
+
+func appendJSONString(buf *[]byte, key, value string) {
+	k, _ := json.Marshal(key)
+	v, _ := json.Marshal(value)
+	*buf = append(*buf, k...)
+	*buf = append(*buf, ':')
+	*buf = append(*buf, v...)
+}
@@ -0,0 +1,107 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLoggerWithFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("with-test")
+	l.SetOutput(&buf)
+	l.SetFlags(0)
+
+	child := l.With("reqID", "abc123")
+	child.Infof("handled request")
+
+	got := buf.String()
+	if !strings.Contains(got, "reqID=abc123") {
+		t.Fatalf("output %q missing field from With", got)
+	}
+
+	grandchild := child.With("attempt", 2)
+	buf.Reset()
+	grandchild.Infof("retry")
+
+	got = buf.String()
+	if !strings.Contains(got, "reqID=abc123") || !strings.Contains(got, "attempt=2") {
+		t.Fatalf("output %q missing fields inherited through With chain", got)
+	}
+}
+
+func TestLoggerWithDoesNotMutateParent(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("with-isolation")
+	l.SetOutput(&buf)
+	l.SetFlags(0)
+
+	_ = l.With("k", "v")
+	l.Infof("plain")
+
+	if strings.Contains(buf.String(), "k=v") {
+		t.Fatalf("With leaked a field onto its parent logger: %q", buf.String())
+	}
+}
+
+func TestLoggerKVMethods(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("kv-test")
+	l.SetOutput(&buf)
+	l.SetFlags(0)
+
+	l.InfoKV("started", "port", 8080)
+
+	got := buf.String()
+	if !strings.Contains(got, "started") || !strings.Contains(got, "port=8080") {
+		t.Fatalf("InfoKV output = %q, want msg and port=8080", got)
+	}
+}
+
+func TestJSONHandlerOutput(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("json-test")
+	l.SetOutput(&buf)
+	l.SetFlags(0)
+	l.SetHandler(JSONHandler{})
+
+	l.With("user", "alice").InfoKV("login", "ip", "10.0.0.1")
+
+	line := strings.TrimSpace(buf.String())
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &decoded); err != nil {
+		t.Fatalf("JSONHandler produced invalid JSON %q: %v", line, err)
+	}
+
+	if decoded["level"] != "info" {
+		t.Errorf("level = %v, want %q", decoded["level"], "info")
+	}
+	if decoded["msg"] != "login" {
+		t.Errorf("msg = %v, want %q", decoded["msg"], "login")
+	}
+	if decoded["user"] != "alice" {
+		t.Errorf("user field = %v, want %q", decoded["user"], "alice")
+	}
+	if decoded["ip"] != "10.0.0.1" {
+		t.Errorf("ip field = %v, want %q", decoded["ip"], "10.0.0.1")
+	}
+}
+
+func TestLoggerSetOutputDiscardSharedWithChild(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("discard-share")
+	l.SetOutput(&buf)
+	child := l.With("k", "v")
+
+	l.SetOutput(io.Discard)
+	if !child.sink.isDiscard.Load() {
+		t.Fatal("child's shared sink did not observe parent's SetOutput(io.Discard)")
+	}
+
+	child.Infof("should not appear")
+	if buf.Len() != 0 {
+		t.Fatalf("child logger wrote %q after parent's SetOutput pointed the shared sink at discard", buf.String())
+	}
+}
@@ -17,7 +17,9 @@ import (
 	"io"
 	"os"
 	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -52,17 +54,87 @@ var levelString = [...]string{
 	"[FATAL]",
 }
 
+// levelNames are the lowercase level tokens used anywhere golog exposes a
+// level as a plain string instead of levelString's bracketed text form:
+// JSONHandler output and the registry's level-control API.
+var levelNames = [...]string{
+	"debug",
+	"info",
+	"warn",
+	"error",
+	"fatal",
+}
+
+func levelName(level int) string {
+	if level < 0 || level >= len(levelNames) {
+		return "unknown"
+	}
+	return levelNames[level]
+}
+
+func levelFromName(name string) (int, bool) {
+	name = strings.ToLower(name)
+	for i, n := range levelNames {
+		if n == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// maxPooledBufSize caps the capacity of a formatting buffer that is allowed
+// back into bufPool, so one occasional huge log line doesn't leave every
+// future caller holding a giant allocation.
+const maxPooledBufSize = 64 * 1024
+
+var bufPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 256)
+		return &b
+	},
+}
+
+func getBuffer() *[]byte {
+	return bufPool.Get().(*[]byte)
+}
+
+func putBuffer(buf *[]byte) {
+	if cap(*buf) > maxPooledBufSize {
+		return
+	}
+	*buf = (*buf)[:0]
+	bufPool.Put(buf)
+}
+
+// sink holds the actual destination a Logger writes to. It is shared by
+// pointer between a Logger and every child created via With, so SetOutput
+// on one repoints all of them, their writes stay serialized through a
+// single mutex, and isDiscard (set when w == io.Discard) stays consistent
+// across every logger sharing the sink rather than only the one that
+// called SetOutput.
+type sink struct {
+	mu        sync.Mutex
+	w         io.Writer
+	isDiscard atomic.Bool
+}
+
 // A Logger represents an active logging object that generates lines of
 // output to an io.Writer.  Each logging operation makes a single call to
 // the Writer's Write method.  A Logger can be used simultaneously from
 // multiple goroutines; it guarantees to serialize access to the Writer.
+//
+// flag, level and name are read far more often than they are written, so
+// they're held in atomics instead of behind the write mutex: formatting a
+// header never has to block on a concurrent SetLevel/SetName call. sink.mu
+// only serializes the actual Write to the destination.
 type Logger struct {
-	mu    sync.Mutex // ensures atomic writes; protects the following fields
-	flag  int        // properties
-	out   io.Writer  // destination for output
-	buf   []byte     // for accumulating text to write
-	level int
-	name  string
+	flag  atomic.Int32 // properties
+	level atomic.Int32
+	name  atomic.Pointer[string]
+
+	sink    *sink
+	handler Handler
+	fields  []Field // immutable context fields attached via With
 }
 
 // New creates a new Logger.   The out variable sets the
@@ -71,13 +143,92 @@ type Logger struct {
 // The flag argument defines the logging properties.
 
 func New(name string) *Logger {
-	l := &Logger{out: os.Stderr, flag: LstdFlags, level: LEVEL_DEBUG, name: name}
+	l := &Logger{sink: &sink{w: os.Stderr}, handler: TextHandler{}}
+	l.flag.Store(int32(LstdFlags))
+	l.level.Store(int32(LEVEL_DEBUG))
+	l.name.Store(&name)
 
 	add(l)
 
 	return l
 }
 
+// SetFlags sets the output flags for the logger.
+func (self *Logger) SetFlags(flag int) {
+	self.flag.Store(int32(flag))
+}
+
+// Flags returns the output flags for the logger.
+func (self *Logger) Flags() int {
+	return int(self.flag.Load())
+}
+
+// SetLevel sets the minimum level the logger will emit.
+func (self *Logger) SetLevel(level int) {
+	self.level.Store(int32(level))
+}
+
+// Level returns the minimum level the logger will emit.
+func (self *Logger) Level() int {
+	return int(self.level.Load())
+}
+
+// SetName sets the name reported in each log line, and re-keys self in the
+// package registry so GetLogger, SetLevelByName and the HTTP level API keep
+// finding it under its new name.
+func (self *Logger) SetName(name string) {
+	old := self.Name()
+	self.name.Store(&name)
+	if name != old {
+		rekey(old, name, self)
+	}
+}
+
+// Name returns the name reported in each log line.
+func (self *Logger) Name() string {
+	if p := self.name.Load(); p != nil {
+		return *p
+	}
+	return ""
+}
+
+// SetOutput sets the output destination for the logger.
+func (self *Logger) SetOutput(w io.Writer) {
+	self.sink.mu.Lock()
+	defer self.sink.mu.Unlock()
+	self.sink.w = w
+	self.sink.isDiscard.Store(w == io.Discard)
+}
+
+// SetHandler selects how log records are rendered, e.g. TextHandler (the
+// default) or JSONHandler for machine-parseable output.
+func (self *Logger) SetHandler(h Handler) {
+	self.handler = h
+}
+
+// With returns a child logger that carries kvs as immutable context fields
+// in addition to any inherited from self. The child shares self's output
+// destination, flags, level and name at the time With is called; later
+// SetFlags/SetLevel/SetName calls on self are not reflected in the child.
+func (self *Logger) With(kvs ...interface{}) *Logger {
+	child := &Logger{sink: self.sink, handler: self.handler}
+	child.flag.Store(self.flag.Load())
+	child.level.Store(self.level.Load())
+	name := self.Name()
+	child.name.Store(&name)
+	child.fields = append(append([]Field{}, self.fields...), toFields(kvs)...)
+	return child
+}
+
+func toFields(kvs []interface{}) []Field {
+	fields := make([]Field, 0, len(kvs)/2)
+	for i := 0; i+1 < len(kvs); i += 2 {
+		key, _ := kvs[i].(string)
+		fields = append(fields, Field{Key: key, Value: kvs[i+1]})
+	}
+	return fields
+}
+
 // Cheap integer to fixed-width decimal ASCII.  Give a negative width to avoid zero-padding.
 // Knows the buffer has capacity.
 func itoa(buf *[]byte, i int, wid int) {
@@ -98,11 +249,11 @@ func itoa(buf *[]byte, i int, wid int) {
 	*buf = append(*buf, b[bp:]...)
 }
 
-func (self *Logger) formatHeader(buf *[]byte, t time.Time, file string, line int, prefix string) {
+func formatHeader(buf *[]byte, flag int, t time.Time, file string, line int, prefix string) {
 	*buf = append(*buf, prefix...)
 	*buf = append(*buf, ' ')
-	if self.flag&(Ldate|Ltime|Lmicroseconds) != 0 {
-		if self.flag&Ldate != 0 {
+	if flag&(Ldate|Ltime|Lmicroseconds) != 0 {
+		if flag&Ldate != 0 {
 			year, month, day := t.Date()
 			itoa(buf, year, 4)
 			*buf = append(*buf, '/')
@@ -111,22 +262,22 @@ func (self *Logger) formatHeader(buf *[]byte, t time.Time, file string, line int
 			itoa(buf, day, 2)
 			*buf = append(*buf, ' ')
 		}
-		if self.flag&(Ltime|Lmicroseconds) != 0 {
+		if flag&(Ltime|Lmicroseconds) != 0 {
 			hour, min, sec := t.Clock()
 			itoa(buf, hour, 2)
 			*buf = append(*buf, ':')
 			itoa(buf, min, 2)
 			*buf = append(*buf, ':')
 			itoa(buf, sec, 2)
-			if self.flag&Lmicroseconds != 0 {
+			if flag&Lmicroseconds != 0 {
 				*buf = append(*buf, '.')
 				itoa(buf, t.Nanosecond()/1e3, 6)
 			}
 			*buf = append(*buf, ' ')
 		}
 	}
-	if self.flag&(Lshortfile|Llongfile) != 0 {
-		if self.flag&Lshortfile != 0 {
+	if flag&(Lshortfile|Llongfile) != 0 {
+		if flag&Lshortfile != 0 {
 			short := file
 			for i := len(file) - 1; i > 0; i-- {
 				if file[i] == '/' {
@@ -149,47 +300,120 @@ func (self *Logger) formatHeader(buf *[]byte, t time.Time, file string, line int
 // already a newline.  Calldepth is used to recover the PC and is
 // provided for generality, although at the moment on all pre-defined
 // paths it will be 2.
+//
+// Output always renders as plain text regardless of the Logger's handler;
+// it is the low-level escape hatch existing callers depend on. Debugf,
+// Infof and the rest go through the handler so they honor SetHandler.
 func (self *Logger) Output(calldepth int, prefix string, s string) error {
+	if self.sink.isDiscard.Load() {
+		return nil
+	}
+
 	now := time.Now() // get this early.
+	flag := int(self.flag.Load())
+
 	var file string
 	var line int
-	self.mu.Lock()
-	defer self.mu.Unlock()
-	if self.flag&(Lshortfile|Llongfile) != 0 {
-		// release lock while getting caller info - it's expensive.
-		self.mu.Unlock()
+	if flag&(Lshortfile|Llongfile) != 0 {
 		var ok bool
 		_, file, line, ok = runtime.Caller(calldepth)
 		if !ok {
 			file = "???"
 			line = 0
 		}
-		self.mu.Lock()
 	}
-	self.buf = self.buf[:0]
-	self.formatHeader(&self.buf, now, file, line, prefix)
-	self.buf = append(self.buf, s...)
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	formatHeader(buf, flag, now, file, line, prefix)
+	*buf = append(*buf, s...)
 	if len(s) > 0 && s[len(s)-1] != '\n' {
-		self.buf = append(self.buf, '\n')
+		*buf = append(*buf, '\n')
+	}
+
+	self.sink.mu.Lock()
+	defer self.sink.mu.Unlock()
+	_, err := self.sink.w.Write(*buf)
+	return err
+}
+
+// write renders a record through the Logger's handler and writes it to the
+// destination. calldepth is relative to write's own caller, mirroring how
+// Output treats calldepth relative to itself.
+func (self *Logger) write(level, calldepth int, msg string, fields []Field) error {
+	if self.sink.isDiscard.Load() {
+		return nil
+	}
+
+	now := time.Now()
+	flag := int(self.flag.Load())
+
+	var file string
+	var line int
+	if flag&(Lshortfile|Llongfile) != 0 {
+		var ok bool
+		_, file, line, ok = runtime.Caller(calldepth)
+		if !ok {
+			file = "???"
+			line = 0
+		}
+	}
+
+	r := Record{
+		Time:   now,
+		Level:  level,
+		Logger: self.Name(),
+		File:   file,
+		Line:   line,
+		Flag:   flag,
+		Msg:    msg,
+		Fields: fields,
 	}
-	_, err := self.out.Write(self.buf)
+
+	h := self.handler
+	if h == nil {
+		h = TextHandler{}
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	h.Handle(buf, &r)
+
+	self.sink.mu.Lock()
+	defer self.sink.mu.Unlock()
+	_, err := self.sink.w.Write(*buf)
 	return err
 }
 
 func (self *Logger) log(level int, format string, v ...interface{}) {
 
-	if level < self.level {
+	if int32(level) < self.level.Load() || self.sink.isDiscard.Load() {
 		return
 	}
 
-	prefix := fmt.Sprintf("%s %s", levelString[level], self.name)
-
+	var msg string
 	if format == "" {
-		self.Output(3, prefix, fmt.Sprintln(v...))
+		msg = fmt.Sprintln(v...)
 	} else {
-		self.Output(3, prefix, fmt.Sprintf(format, v...))
+		msg = fmt.Sprintf(format, v...)
+	}
+
+	self.write(level, 3, msg, self.fields)
+}
+
+// kv handles the DebugKV/InfoKV/... family: msg plus alternating key/value
+// pairs appended to any fields already attached via With.
+func (self *Logger) kv(level int, msg string, kvs ...interface{}) {
+	if int32(level) < self.level.Load() || self.sink.isDiscard.Load() {
+		return
+	}
+
+	fields := self.fields
+	if len(kvs) > 0 {
+		fields = append(append([]Field{}, self.fields...), toFields(kvs)...)
 	}
 
+	self.write(level, 3, msg, fields)
 }
 
 func (self *Logger) Debugf(format string, v ...interface{}) {
@@ -201,6 +425,10 @@ func (self *Logger) Debugln(v ...interface{}) {
 	self.log(LEVEL_DEBUG, "", v...)
 }
 
+func (self *Logger) DebugKV(msg string, kvs ...interface{}) {
+	self.kv(LEVEL_DEBUG, msg, kvs...)
+}
+
 func (self *Logger) Infof(format string, v ...interface{}) {
 
 	self.log(LEVEL_INFO, format, v...)
@@ -210,6 +438,10 @@ func (self *Logger) Infoln(v ...interface{}) {
 	self.log(LEVEL_INFO, "", v...)
 }
 
+func (self *Logger) InfoKV(msg string, kvs ...interface{}) {
+	self.kv(LEVEL_INFO, msg, kvs...)
+}
+
 func (self *Logger) Warnf(format string, v ...interface{}) {
 
 	self.log(LEVEL_WARN, format, v...)
@@ -219,6 +451,10 @@ func (self *Logger) Warnln(v ...interface{}) {
 	self.log(LEVEL_WARN, "", v...)
 }
 
+func (self *Logger) WarnKV(msg string, kvs ...interface{}) {
+	self.kv(LEVEL_WARN, msg, kvs...)
+}
+
 func (self *Logger) Errorf(format string, v ...interface{}) {
 
 	self.log(LEVEL_ERROR, format, v...)
@@ -228,6 +464,10 @@ func (self *Logger) Errorln(v ...interface{}) {
 	self.log(LEVEL_ERROR, "", v...)
 }
 
+func (self *Logger) ErrorKV(msg string, kvs ...interface{}) {
+	self.kv(LEVEL_ERROR, msg, kvs...)
+}
+
 func (self *Logger) Fatalf(format string, v ...interface{}) {
 
 	self.log(LEVEL_FATAL, format, v...)
@@ -236,3 +476,7 @@ func (self *Logger) Fatalf(format string, v ...interface{}) {
 func (self *Logger) Fatalln(v ...interface{}) {
 	self.log(LEVEL_FATAL, "", v...)
 }
+
+func (self *Logger) FatalKV(msg string, kvs ...interface{}) {
+	self.kv(LEVEL_FATAL, msg, kvs...)
+}
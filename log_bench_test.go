@@ -0,0 +1,73 @@
+package golog
+
+import (
+	"io"
+	"testing"
+)
+
+// sinkWriter is a no-op io.Writer that, unlike io.Discard, isn't the
+// identity SetOutput's isDiscard check looks for. Benchmarks meant to
+// measure the header-formatting/pool/mutex path use it instead of
+// io.Discard, so that path doesn't short-circuit before it can be measured.
+type sinkWriter struct{}
+
+func (sinkWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+// BenchmarkInfofParallel exercises Infof (log -> write -> Handler) from many
+// goroutines at once to show that header formatting (atomics + pooled
+// buffer) no longer serializes behind a single mutex; only the final Write
+// does.
+func BenchmarkInfofParallel(b *testing.B) {
+	l := New("bench")
+	l.SetOutput(sinkWriter{})
+	l.SetFlags(LstdFlags | Lshortfile)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Infof("hello %d", 42)
+		}
+	})
+}
+
+// BenchmarkDisabledLevel shows that a call below the logger's level never
+// formats its arguments or looks up the caller.
+func BenchmarkDisabledLevel(b *testing.B) {
+	l := New("bench-disabled")
+	l.SetOutput(sinkWriter{})
+	l.SetFlags(LstdFlags | Lshortfile)
+	l.SetLevel(LEVEL_ERROR)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Debugf("hello %d", 42)
+	}
+}
+
+// BenchmarkInfofSequential is BenchmarkInfofParallel's single-goroutine
+// counterpart: same header-formatting/pool/mutex path, without contention.
+func BenchmarkInfofSequential(b *testing.B) {
+	l := New("bench-sequential")
+	l.SetOutput(sinkWriter{})
+	l.SetFlags(LstdFlags | Lshortfile)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Infof("hello %d", 42)
+	}
+}
+
+// BenchmarkDiscardFastPath shows that logging to io.Discard is effectively
+// free: isDiscard short-circuits before formatting or caller lookup. This is
+// the one benchmark in this file that's supposed to hit that short-circuit;
+// the others use sinkWriter precisely so they don't.
+func BenchmarkDiscardFastPath(b *testing.B) {
+	l := New("bench-discard")
+	l.SetOutput(io.Discard)
+	l.SetFlags(LstdFlags | Lshortfile)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Infof("hello %d", 42)
+	}
+}
@@ -0,0 +1,180 @@
+package golog
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]*Logger{}
+
+	// createMu serializes the check-then-create sequence in GetLogger so
+	// two concurrent first-time lookups of the same name can't each
+	// construct and register their own Logger, orphaning one of them.
+	createMu sync.Mutex
+)
+
+// add registers l under its name so it can be found with GetLogger and
+// reached by SetGlobalLevel/SetLevelByName.
+func add(l *Logger) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[l.Name()] = l
+}
+
+// rekey moves l's registry entry from oldName to newName after SetName
+// changes it. It leaves oldName's entry alone if it no longer points at l,
+// e.g. another Logger has since been registered under that name.
+func rekey(oldName, newName string, l *Logger) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if registry[oldName] == l {
+		delete(registry, oldName)
+	}
+	registry[newName] = l
+}
+
+// GetLogger returns the previously registered Logger named name, creating
+// and registering one with the default settings if none exists yet.
+func GetLogger(name string) *Logger {
+	if l, ok := lookupLogger(name); ok {
+		return l
+	}
+
+	createMu.Lock()
+	defer createMu.Unlock()
+	if l, ok := lookupLogger(name); ok {
+		return l
+	}
+	return New(name)
+}
+
+func lookupLogger(name string) (*Logger, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	l, ok := registry[name]
+	return l, ok
+}
+
+// SetGlobalLevel atomically sets level on every currently registered
+// Logger.
+func SetGlobalLevel(level int) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for _, l := range registry {
+		l.SetLevel(level)
+	}
+}
+
+// SetLevelByName sets level on every registered Logger whose name matches
+// pattern, a path.Match glob such as "net.*". It returns an error only if
+// pattern itself is malformed; matching zero loggers is not an error.
+func SetLevelByName(pattern string, level int) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return err
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for name, l := range registry {
+		matched, err := path.Match(pattern, name)
+		if err != nil {
+			return err
+		}
+		if matched {
+			l.SetLevel(level)
+		}
+	}
+	return nil
+}
+
+// levelPayload is the JSON body LevelHandler reads and writes.
+// Logger is a path.Match pattern over registered logger names; an empty
+// Logger means "every registered logger".
+type levelPayload struct {
+	Logger string `json:"logger,omitempty"`
+	Level  string `json:"level"`
+}
+
+// LevelHandler returns an http.Handler that lets operators inspect and
+// change registered loggers' levels on a running service:
+//
+//	GET  /debug/loglevel            -> levels of every registered logger
+//	GET  /debug/loglevel?logger=foo -> level of logger "foo", if registered
+//	PUT  /debug/loglevel {"level":"debug"}                 -> sets every logger
+//	PUT  /debug/loglevel {"logger":"net.*","level":"debug"} -> sets matching loggers
+//
+// Callers mount it at whatever path they like, e.g.
+// http.Handle("/debug/loglevel", golog.LevelHandler()).
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handleGetLevel(w, r)
+		case http.MethodPut:
+			handlePutLevel(w, r)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "golog: method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// handleGetLevel is read-only: unlike GetLogger, a lookup miss is reported
+// as 404 rather than registering a new Logger, so an operator polling
+// arbitrary names can't be used to grow the registry unbounded.
+func handleGetLevel(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("logger")
+	if name == "" {
+		registryMu.RLock()
+		levels := make(map[string]string, len(registry))
+		for n, l := range registry {
+			levels[n] = levelName(l.Level())
+		}
+		registryMu.RUnlock()
+		writeLevelJSON(w, levels)
+		return
+	}
+
+	l, ok := lookupLogger(name)
+	if !ok {
+		http.Error(w, "golog: no such logger "+name, http.StatusNotFound)
+		return
+	}
+
+	writeLevelJSON(w, levelPayload{Logger: name, Level: levelName(l.Level())})
+}
+
+func handlePutLevel(w http.ResponseWriter, r *http.Request) {
+	var p levelPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, "golog: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if p.Logger == "" {
+		p.Logger = r.URL.Query().Get("logger")
+	}
+
+	level, ok := levelFromName(p.Level)
+	if !ok {
+		http.Error(w, "golog: unknown level "+p.Level, http.StatusBadRequest)
+		return
+	}
+
+	if p.Logger == "" {
+		SetGlobalLevel(level)
+	} else if err := SetLevelByName(p.Logger, level); err != nil {
+		http.Error(w, "golog: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeLevelJSON(w, levelPayload{Logger: p.Logger, Level: levelName(level)})
+}
+
+func writeLevelJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
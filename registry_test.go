@@ -0,0 +1,145 @@
+package golog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetLoggerRegistersAndReuses(t *testing.T) {
+	name := "registry-test-" + t.Name()
+	l1 := GetLogger(name)
+	l2 := GetLogger(name)
+	if l1 != l2 {
+		t.Fatal("GetLogger returned a different Logger for the same name on the second call")
+	}
+}
+
+func TestSetNameRekeysRegistry(t *testing.T) {
+	oldName := "registry-test-rekey-old"
+	newName := "registry-test-rekey-new"
+
+	l := GetLogger(oldName)
+	l.SetName(newName)
+
+	if got := GetLogger(newName); got != l {
+		t.Fatal("GetLogger(newName) did not find the renamed Logger")
+	}
+	if _, ok := lookupLogger(oldName); ok {
+		t.Fatal("renamed Logger is still reachable under its old name")
+	}
+
+	l.SetLevel(LEVEL_DEBUG)
+	if err := SetLevelByName(newName, LEVEL_ERROR); err != nil {
+		t.Fatalf("SetLevelByName: %v", err)
+	}
+	if l.Level() != LEVEL_ERROR {
+		t.Fatalf("SetLevelByName(newName) did not reach the renamed Logger: level = %d", l.Level())
+	}
+}
+
+func TestSetLevelByNameGlob(t *testing.T) {
+	a := GetLogger("registry-test.net.http")
+	b := GetLogger("registry-test.net.rpc")
+	c := GetLogger("registry-test.db")
+	a.SetLevel(LEVEL_DEBUG)
+	b.SetLevel(LEVEL_DEBUG)
+	c.SetLevel(LEVEL_DEBUG)
+
+	if err := SetLevelByName("registry-test.net.*", LEVEL_ERROR); err != nil {
+		t.Fatalf("SetLevelByName: %v", err)
+	}
+
+	if a.Level() != LEVEL_ERROR || b.Level() != LEVEL_ERROR {
+		t.Fatalf("matching loggers not updated: a=%d b=%d, want %d", a.Level(), b.Level(), LEVEL_ERROR)
+	}
+	if c.Level() != LEVEL_DEBUG {
+		t.Fatalf("non-matching logger c changed: %d, want %d", c.Level(), LEVEL_DEBUG)
+	}
+}
+
+func TestSetLevelByNameBadPattern(t *testing.T) {
+	if err := SetLevelByName("[", LEVEL_INFO); err == nil {
+		t.Fatal("want error for malformed glob pattern")
+	}
+}
+
+func TestLevelHandlerGetMissingLogger(t *testing.T) {
+	h := LevelHandler()
+	req := httptest.NewRequest(http.MethodGet, "/debug/loglevel?logger=no-such-logger-xyz", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("GET for unregistered logger = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestLevelHandlerGetMissingLoggerDoesNotRegisterIt(t *testing.T) {
+	h := LevelHandler()
+	name := "registry-test-should-not-exist"
+	req := httptest.NewRequest(http.MethodGet, "/debug/loglevel?logger="+name, nil)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if _, ok := lookupLogger(name); ok {
+		t.Fatal("GET on an unknown logger name registered it, letting an unauthenticated GET grow the registry")
+	}
+}
+
+func TestLevelHandlerGetAndPut(t *testing.T) {
+	name := "registry-test-handler"
+	l := GetLogger(name)
+	l.SetLevel(LEVEL_INFO)
+
+	h := LevelHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/loglevel?logger="+name, nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET status = %d, want 200", rec.Code)
+	}
+	var got levelPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode GET body: %v", err)
+	}
+	if got.Level != "info" {
+		t.Fatalf("GET level = %q, want %q", got.Level, "info")
+	}
+
+	body := strings.NewReader(`{"logger":"` + name + `","level":"error"}`)
+	putReq := httptest.NewRequest(http.MethodPut, "/debug/loglevel", body)
+	putRec := httptest.NewRecorder()
+	h.ServeHTTP(putRec, putReq)
+	if putRec.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, want 200, body=%s", putRec.Code, putRec.Body.String())
+	}
+	if l.Level() != LEVEL_ERROR {
+		t.Fatalf("logger level after PUT = %d, want %d", l.Level(), LEVEL_ERROR)
+	}
+}
+
+func TestLevelHandlerPutUnknownLevel(t *testing.T) {
+	h := LevelHandler()
+	body := strings.NewReader(`{"level":"not-a-level"}`)
+	req := httptest.NewRequest(http.MethodPut, "/debug/loglevel", body)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("PUT with unknown level = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestLevelHandlerMethodNotAllowed(t *testing.T) {
+	h := LevelHandler()
+	req := httptest.NewRequest(http.MethodDelete, "/debug/loglevel", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("DELETE = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
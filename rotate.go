@@ -0,0 +1,308 @@
+package golog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotatingFileWriter is an io.Writer that writes to Filename and rotates it
+// once it grows past MaxSize and/or when the local day changes, if
+// DailyRotation is set. It is safe for concurrent Write calls from multiple
+// goroutines and reopens Filename on SIGHUP, so external tools like
+// logrotate can rename the file out from under it.
+//
+// The zero value, with Filename set, is a ready to use writer; all other
+// fields are optional and default to "no limit".
+type RotatingFileWriter struct {
+	// Filename is the file log entries are written to.
+	Filename string
+
+	// MaxSize is the size in bytes a file may reach before it is rotated.
+	// 0 disables size-based rotation.
+	MaxSize int64
+
+	// MaxAge is how long a rotated backup is kept before it is removed.
+	// 0 means backups are never removed because of age.
+	MaxAge time.Duration
+
+	// MaxBackups is the maximum number of rotated backups to retain. 0
+	// means all backups are retained.
+	MaxBackups int
+
+	// Compress gzips a backup once it has been rotated out.
+	Compress bool
+
+	// DailyRotation additionally rotates the file at local midnight.
+	DailyRotation bool
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	openDay int // day-of-year the current file was opened on, for DailyRotation
+
+	hupOnce sync.Once
+	hupChan chan os.Signal
+}
+
+// NewRotatingFileWriter returns a RotatingFileWriter for filename with
+// otherwise default (no limit) rotation settings.
+func NewRotatingFileWriter(filename string) *RotatingFileWriter {
+	return &RotatingFileWriter{Filename: filename}
+}
+
+// Write implements io.Writer. It rotates the destination file first if
+// writing p would push it past MaxSize, or if DailyRotation is set and the
+// local day has changed since the file was opened.
+func (self *RotatingFileWriter) Write(p []byte) (int, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	self.watchHUP()
+
+	if err := self.ensureOpen(); err != nil {
+		return 0, err
+	}
+
+	if self.needsRotate(len(p)) {
+		if err := self.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := self.file.Write(p)
+	self.size += int64(n)
+	return n, err
+}
+
+func (self *RotatingFileWriter) needsRotate(writeLen int) bool {
+	if self.MaxSize > 0 && self.size+int64(writeLen) > self.MaxSize {
+		return true
+	}
+	if self.DailyRotation && time.Now().YearDay() != self.openDay {
+		return true
+	}
+	return false
+}
+
+func (self *RotatingFileWriter) ensureOpen() error {
+	if self.file != nil {
+		return nil
+	}
+	return self.openExisting()
+}
+
+// openExisting opens Filename for append, creating it and any parent
+// directories if necessary, and primes size/openDay from its current state.
+func (self *RotatingFileWriter) openExisting() error {
+	if dir := filepath.Dir(self.Filename); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("golog: cannot create log directory: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(self.Filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("golog: cannot open log file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("golog: cannot stat log file: %w", err)
+	}
+
+	self.file = f
+	self.size = info.Size()
+	self.openDay = time.Now().YearDay()
+	return nil
+}
+
+// rotate closes the current file, renames it to a timestamped backup,
+// opens a fresh file in its place, and prunes old backups.
+func (self *RotatingFileWriter) rotate() error {
+	if self.file != nil {
+		self.file.Close()
+		self.file = nil
+	}
+
+	backup := self.backupName()
+	if _, err := os.Stat(self.Filename); err == nil {
+		if err := os.Rename(self.Filename, backup); err != nil {
+			return fmt.Errorf("golog: cannot rename log file for rotation: %w", err)
+		}
+		if self.Compress {
+			go compressBackup(backup)
+		}
+	}
+
+	if err := self.openExisting(); err != nil {
+		return err
+	}
+
+	go self.prune()
+	return nil
+}
+
+func (self *RotatingFileWriter) backupName() string {
+	dir := filepath.Dir(self.Filename)
+	base := filepath.Base(self.Filename)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	return filepath.Join(dir, fmt.Sprintf("%s-%s%s", stem, time.Now().Format(backupTimeFormat), ext))
+}
+
+// compressBackup gzips path into path+".gz" and removes path. It writes to a
+// ".gz.tmp" sibling and renames it into place only once the gzip stream is
+// fully written and closed, so path+".gz" never appears in the directory
+// truncated or mid-write for a concurrent reader such as a log shipper.
+func compressBackup(path string) {
+	src, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer src.Close()
+
+	tmpPath := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(tmpPath)
+		return
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+		return
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmpPath)
+		return
+	}
+
+	if err := os.Rename(tmpPath, path+".gz"); err != nil {
+		os.Remove(tmpPath)
+		return
+	}
+
+	os.Remove(path)
+}
+
+// prune removes backups of Filename beyond MaxBackups and older than
+// MaxAge. It runs in its own goroutine after each rotation, so a slow
+// directory listing never blocks Write.
+func (self *RotatingFileWriter) prune() {
+	if self.MaxBackups <= 0 && self.MaxAge <= 0 {
+		return
+	}
+
+	dir := filepath.Dir(self.Filename)
+	base := filepath.Base(self.Filename)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	prefix := stem + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	var backups []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		if name == base || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if !strings.HasSuffix(name, ext) && !strings.HasSuffix(name, ext+".gz") {
+			continue
+		}
+		backups = append(backups, filepath.Join(dir, name))
+	}
+
+	sort.Strings(backups) // backupTimeFormat sorts lexically in chronological order
+
+	if self.MaxAge > 0 {
+		cutoff := time.Now().Add(-self.MaxAge)
+		kept := backups[:0]
+		for _, path := range backups {
+			info, err := os.Stat(path)
+			if err == nil && info.ModTime().Before(cutoff) {
+				os.Remove(path)
+				continue
+			}
+			kept = append(kept, path)
+		}
+		backups = kept
+	}
+
+	if self.MaxBackups > 0 && len(backups) > self.MaxBackups {
+		for _, path := range backups[:len(backups)-self.MaxBackups] {
+			os.Remove(path)
+		}
+	}
+}
+
+// watchHUP arranges for the file to be reopened the next time it's needed
+// whenever the process receives SIGHUP, matching the logrotate convention
+// of renaming a file and signalling the writer to pick up a fresh one.
+func (self *RotatingFileWriter) watchHUP() {
+	self.hupOnce.Do(func() {
+		self.hupChan = make(chan os.Signal, 1)
+		signal.Notify(self.hupChan, syscall.SIGHUP)
+		go func(c chan os.Signal) {
+			for range c {
+				self.mu.Lock()
+				if self.file != nil {
+					self.file.Close()
+					self.file = nil
+				}
+				self.mu.Unlock()
+			}
+		}(self.hupChan)
+	})
+}
+
+// Close closes the underlying file, if open, and stops this writer's SIGHUP
+// watcher so it doesn't outlive the writer.
+func (self *RotatingFileWriter) Close() error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if self.hupChan != nil {
+		signal.Stop(self.hupChan)
+		close(self.hupChan)
+		self.hupChan = nil
+	}
+
+	if self.file == nil {
+		return nil
+	}
+	err := self.file.Close()
+	self.file = nil
+	return err
+}
+
+// MultiWriter returns a Writer that duplicates each Write to all of
+// writers, e.g. os.Stderr and a RotatingFileWriter.
+func MultiWriter(writers ...io.Writer) io.Writer {
+	return io.MultiWriter(writers...)
+}
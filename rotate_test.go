@@ -0,0 +1,154 @@
+package golog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileWriterSizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	w := NewRotatingFileWriter(filepath.Join(dir, "app.log"))
+	w.MaxSize = 10
+	defer w.Close()
+
+	if _, err := w.Write([]byte("1234567890")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, err := w.Write([]byte("next")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("want 2 files (active + 1 backup) after exceeding MaxSize, got %d: %v", len(entries), entries)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "next" {
+		t.Fatalf("active file = %q, want %q", data, "next")
+	}
+}
+
+func TestRotatingFileWriterMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	w := NewRotatingFileWriter(filepath.Join(dir, "app.log"))
+	w.MaxSize = 1
+	w.MaxBackups = 2
+	defer w.Close()
+
+	for i := 0; i < 5; i++ {
+		if _, err := w.Write([]byte("x")); err != nil {
+			t.Fatalf("Write #%d: %v", i, err)
+		}
+		// prune runs asynchronously after rotate; give it a moment.
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	// one active file plus at most MaxBackups rotated ones.
+	if len(entries) > w.MaxBackups+1 {
+		t.Fatalf("want at most %d files, got %d: %v", w.MaxBackups+1, len(entries), entries)
+	}
+}
+
+func TestRotatingFileWriterCompress(t *testing.T) {
+	dir := t.TempDir()
+	w := NewRotatingFileWriter(filepath.Join(dir, "app.log"))
+	w.MaxSize = 1
+	w.Compress = true
+	defer w.Close()
+
+	if _, err := w.Write([]byte("a")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, err := w.Write([]byte("b")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	var gzPath string
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("ReadDir: %v", err)
+		}
+		for _, e := range entries {
+			if filepath.Ext(e.Name()) == ".gz" {
+				gzPath = filepath.Join(dir, e.Name())
+			}
+		}
+		if gzPath != "" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if gzPath == "" {
+		t.Fatal("no .gz backup appeared after rotation with Compress set")
+	}
+
+	f, err := os.Open(gzPath)
+	if err != nil {
+		t.Fatalf("Open(%s): %v", gzPath, err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	defer gz.Close()
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("read gzip contents: %v", err)
+	}
+	if string(data) != "a" {
+		t.Fatalf("compressed backup contents = %q, want %q", data, "a")
+	}
+}
+
+func TestRotatingFileWriterNeedsRotateDaily(t *testing.T) {
+	w := NewRotatingFileWriter(filepath.Join(t.TempDir(), "app.log"))
+	w.DailyRotation = true
+	w.openDay = time.Now().YearDay() - 1
+
+	if !w.needsRotate(0) {
+		t.Fatal("needsRotate should report true once the day has changed")
+	}
+
+	w.openDay = time.Now().YearDay()
+	if w.needsRotate(0) {
+		t.Fatal("needsRotate should report false on the same day with no size limit")
+	}
+}
+
+func TestMultiWriter(t *testing.T) {
+	var a, b bytesBuffer
+	w := MultiWriter(&a, &b)
+	if _, err := w.Write([]byte("hi")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if a.s != "hi" || b.s != "hi" {
+		t.Fatalf("MultiWriter did not tee to both writers: a=%q b=%q", a.s, b.s)
+	}
+}
+
+// bytesBuffer is a minimal io.Writer so this test doesn't need bytes.Buffer's
+// wider API.
+type bytesBuffer struct{ s string }
+
+func (b *bytesBuffer) Write(p []byte) (int, error) {
+	b.s += string(p)
+	return len(p), nil
+}